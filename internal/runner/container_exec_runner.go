@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	dockererrdefs "github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin/config"
+	"github.com/hashicorp/go-plugin/internal/runner/errdefs"
+)
+
+var _ Runner = (*ContainerExecRunner)(nil)
+
+// execPollInterval is how often Wait polls ContainerExecInspect for the
+// exec process's exit code, since the Docker API has no blocking wait for
+// exec processes the way it does for containers.
+const execPollInterval = 500 * time.Millisecond
+
+// wrapExecErr classifies an error from the Docker exec API into this
+// package's own errdefs types, for example when err reflects exec'ing
+// into a container that has already exited.
+func wrapExecErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case client.IsErrNotFound(err):
+		return errdefs.NewNotFound(err)
+	case dockererrdefs.IsConflict(err):
+		return errdefs.NewConflict(err)
+	default:
+		return err
+	}
+}
+
+// ContainerExecRunner implements the Runner interface by launching the
+// plugin binary inside an already-running container via Docker's exec
+// API, rather than creating a new container of its own. It's intended for
+// plugins co-located inside sidecar containers or long-lived sandboxes
+// managed outside go-plugin's lifecycle.
+//
+// Kill requires a "kill" binary in the target container, since Docker has
+// no API to terminate an exec process directly; minimal or distroless
+// images without a shell/coreutils aren't supported.
+type ContainerExecRunner struct {
+	logger hclog.Logger
+
+	cmd    *exec.Cmd
+	config *config.ContainerExecConfig
+
+	hostSocketDir string
+
+	dockerClient *client.Client
+	execID       string
+
+	stdout io.ReadCloser
+	stderr io.ReadCloser
+}
+
+// NewContainerExecRunner must be passed a cmd that hasn't yet been
+// started.
+func NewContainerExecRunner(logger hclog.Logger, cmd *exec.Cmd, cfg *config.ContainerExecConfig, hostSocketDir string) (*ContainerExecRunner, error) {
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+
+	injectUnixSocketEnv(cmd, cfg.UnixSocketGroup)
+
+	return &ContainerExecRunner{
+		logger:        logger,
+		cmd:           cmd,
+		config:        cfg,
+		hostSocketDir: hostSocketDir,
+		dockerClient:  dockerClient,
+	}, nil
+}
+
+func (c *ContainerExecRunner) Start() error {
+	ctx := context.Background()
+	execConfig := types.ExecConfig{
+		Cmd:          append([]string{c.cmd.Path}, c.cmd.Args[1:]...),
+		Env:          c.cmd.Env,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	resp, err := c.dockerClient.ContainerExecCreate(ctx, c.config.ContainerID, execConfig)
+	if err != nil {
+		return wrapExecErr(err)
+	}
+	c.execID = resp.ID
+
+	hijacked, err := c.dockerClient.ContainerExecAttach(ctx, c.execID, types.ExecStartCheck{})
+	if err != nil {
+		return wrapExecErr(err)
+	}
+
+	var stdoutWriter, stderrWriter io.WriteCloser
+	c.stdout, stdoutWriter = io.Pipe()
+	c.stderr, stderrWriter = io.Pipe()
+	go func() {
+		defer func() {
+			c.logger.Trace("container exec logging goroutine shutting down", "id", c.execID)
+			hijacked.Close()
+			stdoutWriter.Close()
+			stderrWriter.Close()
+		}()
+
+		if _, err := stdcopy.StdCopy(stdoutWriter, stderrWriter, hijacked.Reader); err != nil {
+			c.logger.Error("error streaming logs from container exec", "id", c.execID, "error", err)
+		}
+	}()
+
+	return wrapExecErr(c.dockerClient.ContainerExecStart(ctx, c.execID, types.ExecStartCheck{}))
+}
+
+func (c *ContainerExecRunner) Wait() error {
+	for {
+		inspect, err := c.dockerClient.ContainerExecInspect(context.Background(), c.execID)
+		if err != nil {
+			return wrapExecErr(err)
+		}
+		if !inspect.Running {
+			if inspect.ExitCode != 0 {
+				return fmt.Errorf("plugin exec process exited with code %d", inspect.ExitCode)
+			}
+			return nil
+		}
+
+		time.Sleep(execPollInterval)
+	}
+}
+
+func (c *ContainerExecRunner) Kill() error {
+	defer c.dockerClient.Close()
+	defer os.RemoveAll(c.hostSocketDir)
+
+	inspect, err := c.dockerClient.ContainerExecInspect(context.Background(), c.execID)
+	if err != nil {
+		return wrapExecErr(err)
+	}
+	if !inspect.Running {
+		return nil
+	}
+
+	// Docker has no API to terminate an exec process directly, so signal
+	// its PID from a short-lived exec in the same container, which shares
+	// its PID namespace with the original exec process. This requires a
+	// "kill" binary to be present in the target container; minimal or
+	// distroless sidecar images without a shell/coreutils will fail here.
+	killConfig := types.ExecConfig{Cmd: []string{"kill", "-TERM", strconv.Itoa(inspect.Pid)}}
+	killExec, err := c.dockerClient.ContainerExecCreate(context.Background(), c.config.ContainerID, killConfig)
+	if err != nil {
+		return fmt.Errorf("creating exec to kill plugin process (requires a \"kill\" binary in the target container): %w", wrapExecErr(err))
+	}
+
+	return wrapExecErr(c.dockerClient.ContainerExecStart(context.Background(), killExec.ID, types.ExecStartCheck{}))
+}
+
+func (c *ContainerExecRunner) Stdout() io.ReadCloser {
+	return c.stdout
+}
+
+func (c *ContainerExecRunner) Stderr() io.ReadCloser {
+	return c.stderr
+}
+
+func (c *ContainerExecRunner) ResolveAddr(network, address string) (net.Addr, error) {
+	return resolveContainerSocketAddr(c.hostSocketDir, network, address)
+}
+
+func (c *ContainerExecRunner) Name() string {
+	return c.config.ContainerID
+}
+
+func (c *ContainerExecRunner) ID() string {
+	return c.execID
+}
@@ -2,23 +2,24 @@ package runner
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
 	"os/exec"
-	"path"
 	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin/config"
-	"github.com/hashicorp/go-plugin/internal/constants"
+	"github.com/hashicorp/go-plugin/internal/runner/errdefs"
 )
 
 var _ Runner = (*ContainerRunner)(nil)
@@ -48,7 +49,6 @@ func NewContainerRunner(logger hclog.Logger, cmd *exec.Cmd, cfg *config.Containe
 	}
 
 	// TODO: Support overriding entrypoint, args, and working dir from cmd
-	const containerSocketDir = "/tmp"
 	cfg.HostConfig.Mounts = append(cfg.HostConfig.Mounts, mount.Mount{
 		Type:     mount.TypeBind,
 		Source:   hostSocketDir,
@@ -64,10 +64,7 @@ func NewContainerRunner(logger hclog.Logger, cmd *exec.Cmd, cfg *config.Containe
 		// ClusterOptions: &mount.ClusterOptions{},
 	})
 	// TODO(tomhjp): Copy and edit instead of edit in place.
-	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", constants.EnvUnixSocketDir, containerSocketDir))
-	if cfg.UnixSocketGroup != 0 {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", constants.EnvUnixSocketGroup, cfg.UnixSocketGroup))
-	}
+	injectUnixSocketEnv(cmd, cfg.UnixSocketGroup)
 	cfg.ContainerConfig.Env = cmd.Env
 
 	return &ContainerRunner{
@@ -82,8 +79,16 @@ func NewContainerRunner(logger hclog.Logger, cmd *exec.Cmd, cfg *config.Containe
 
 func (c *ContainerRunner) Start() error {
 	ctx := context.Background()
+
+	if err := c.pullImage(ctx); err != nil {
+		return err
+	}
+
 	resp, err := c.dockerClient.ContainerCreate(ctx, c.config.ContainerConfig, c.config.HostConfig, c.config.NetworkConfig, nil, "")
 	if err != nil {
+		if client.IsErrNotFound(err) {
+			return errdefs.NewNotFound(err)
+		}
 		return err
 	}
 	c.id = resp.ID
@@ -120,17 +125,124 @@ func (c *ContainerRunner) Start() error {
 	return nil
 }
 
+// pullImage ensures c.image is present locally according to the
+// configured ImagePullPolicy, streaming pull progress to the logger, and
+// then verifies the resolved digest against cfg.ExpectedDigest when one
+// is set. The digest check runs on every path, including an
+// already-present or never-pulled image, since a pre-pulled or
+// since-tampered-with local image is exactly what pinning needs to catch.
+func (c *ContainerRunner) pullImage(ctx context.Context) error {
+	switch c.config.ImagePullPolicy {
+	case config.ImagePullNever:
+		// Nothing to pull; fall through to verify whatever is local.
+	case config.ImagePullIfNotPresent:
+		if _, _, err := c.dockerClient.ImageInspectWithRaw(ctx, c.image); err == nil {
+			break
+		} else if !client.IsErrNotFound(err) {
+			return err
+		} else if err := c.pull(ctx); err != nil {
+			return err
+		}
+	case config.ImagePullAlways:
+		if err := c.pull(ctx); err != nil {
+			return err
+		}
+	default:
+		// Unset or unrecognised policy: pull nothing, as with
+		// ImagePullNever, but still verify whatever is local.
+	}
+
+	return c.verifyExpectedDigest(ctx)
+}
+
+// pull pulls c.image from its registry, streaming progress to the
+// logger.
+func (c *ContainerRunner) pull(ctx context.Context) error {
+	var pullOpts types.ImagePullOptions
+	if c.config.RegistryAuth != nil {
+		auth, err := c.config.RegistryAuth(c.image)
+		if err != nil {
+			return errdefs.NewUnauthorized(err)
+		}
+		pullOpts.RegistryAuth = auth
+	}
+
+	rc, err := c.dockerClient.ImagePull(ctx, c.image, pullOpts)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return errdefs.NewNotFound(err)
+		}
+		return err
+	}
+	defer rc.Close()
+
+	dec := json.NewDecoder(rc)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		c.logger.Debug("pulling image", "image", c.image, "status", msg.Status, "progress", msg.Progress)
+	}
+
+	return nil
+}
+
+// verifyExpectedDigest checks the locally resolved digest for c.image
+// against cfg.ExpectedDigest, if one is configured.
+func (c *ContainerRunner) verifyExpectedDigest(ctx context.Context) error {
+	if c.config.ExpectedDigest == "" {
+		return nil
+	}
+
+	inspect, _, err := c.dockerClient.ImageInspectWithRaw(ctx, c.image)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return errdefs.NewNotFound(err)
+		}
+		return err
+	}
+	if !matchesDigest(inspect.RepoDigests, c.config.ExpectedDigest) {
+		return fmt.Errorf("resolved digest for image %s does not match expected digest %s", c.image, c.config.ExpectedDigest)
+	}
+
+	return nil
+}
+
+// matchesDigest reports whether expected appears as the digest component
+// of any of repoDigests, which are of the form "repo@sha256:...".
+func matchesDigest(repoDigests []string, expected string) bool {
+	for _, rd := range repoDigests {
+		if strings.HasSuffix(rd, "@"+expected) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *ContainerRunner) Wait() error {
 	statusCh, errCh := c.dockerClient.ContainerWait(context.Background(), c.id, container.WaitConditionNotRunning)
 	select {
 	case err := <-errCh:
 		if err != nil {
+			if client.IsErrNotFound(err) {
+				return errdefs.NewNotFound(err)
+			}
 			return err
 		}
 	case st := <-statusCh:
 		c.logger.Info("received status update", "status", st)
 		if st.Error != nil {
-			return errors.New(st.Error.Message)
+			err := errors.New(st.Error.Message)
+			if inspect, inspectErr := c.dockerClient.ContainerInspect(context.Background(), c.id); inspectErr == nil && inspect.State != nil && inspect.State.OOMKilled {
+				return errdefs.NewSystemError(err)
+			}
+			return err
 		}
 		return nil
 	}
@@ -143,7 +255,12 @@ func (c *ContainerRunner) Kill() error {
 	defer c.dockerClient.Close()
 	defer os.RemoveAll(c.hostSocketDir)
 	if c.id != "" {
-		return c.dockerClient.ContainerStop(context.Background(), c.id, container.StopOptions{})
+		if err := c.dockerClient.ContainerStop(context.Background(), c.id, container.StopOptions{}); err != nil {
+			if client.IsErrNotFound(err) {
+				return errdefs.NewNotFound(err)
+			}
+			return err
+		}
 	}
 
 	return nil
@@ -158,17 +275,7 @@ func (c *ContainerRunner) Stderr() io.ReadCloser {
 }
 
 func (c *ContainerRunner) ResolveAddr(network, address string) (net.Addr, error) {
-	switch network {
-	case "unix":
-		if !strings.HasPrefix(address, "PLUGIN_UNIX_SOCKET_DIR:") {
-			return nil, errors.New("plugin is running inside container but needs an update to be compatible")
-		}
-
-		address = path.Join(c.hostSocketDir, strings.TrimPrefix(address, "PLUGIN_UNIX_SOCKET_DIR:"))
-		return net.ResolveUnixAddr("unix", address)
-	default:
-		return nil, fmt.Errorf("unsupported address: %s, %s", network, address)
-	}
+	return resolveContainerSocketAddr(c.hostSocketDir, network, address)
 }
 
 func (c *ContainerRunner) Name() string {
@@ -0,0 +1,26 @@
+package runner
+
+import "testing"
+
+func TestMatchesDigest(t *testing.T) {
+	const expected = "sha256:abcd1234"
+
+	cases := []struct {
+		name        string
+		repoDigests []string
+		want        bool
+	}{
+		{"exact match", []string{"example.com/repo@sha256:abcd1234"}, true},
+		{"no match", []string{"example.com/repo@sha256:ffff0000"}, false},
+		{"no repo digests", nil, false},
+		{"matches one of several", []string{"example.com/repo@sha256:ffff0000", "example.com/repo@sha256:abcd1234"}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matchesDigest(tc.repoDigests, expected); got != tc.want {
+				t.Errorf("matchesDigest(%v, %q) = %v, want %v", tc.repoDigests, expected, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/containerd/oci"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin/config"
+)
+
+var _ Runner = (*ContainerdRunner)(nil)
+
+// ContainerdRunner implements the Runner interface by talking directly to
+// a containerd socket, rather than going via the Docker daemon. It's
+// intended for hosts that ship containerd without Docker, such as k8s
+// nodes and other minimal environments.
+type ContainerdRunner struct {
+	logger hclog.Logger
+
+	cmd    *exec.Cmd
+	config *config.ContainerdConfig
+
+	hostSocketDir string
+
+	client    *containerd.Client
+	container containerd.Container
+	task      containerd.Task
+	exitCh    <-chan containerd.ExitStatus
+
+	stdout io.ReadCloser
+	stderr io.ReadCloser
+
+	image string
+	id    string
+}
+
+// NewContainerdRunner must be passed a cmd that hasn't yet been started.
+func NewContainerdRunner(logger hclog.Logger, cmd *exec.Cmd, cfg *config.ContainerdConfig, hostSocketDir string) (*ContainerdRunner, error) {
+	client, err := containerd.New(cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	injectUnixSocketEnv(cmd, cfg.UnixSocketGroup)
+
+	return &ContainerdRunner{
+		logger:        logger,
+		cmd:           cmd,
+		config:        cfg,
+		hostSocketDir: hostSocketDir,
+		client:        client,
+		image:         cfg.Image,
+		id:            newRunnerID(),
+	}, nil
+}
+
+func (c *ContainerdRunner) Start() error {
+	ctx := namespaces.WithNamespace(context.Background(), c.config.Namespace)
+
+	image, err := c.client.Pull(ctx, c.image, containerd.WithPullUnpack)
+	if err != nil {
+		return err
+	}
+
+	cont, err := c.client.NewContainer(
+		ctx,
+		c.id,
+		containerd.WithImage(image),
+		containerd.WithNewSnapshot(c.id+"-snapshot", image),
+		containerd.WithNewSpec(
+			oci.WithImageConfig(image),
+			oci.WithProcessArgs(append([]string{c.cmd.Path}, c.cmd.Args[1:]...)...),
+			oci.WithEnv(c.cmd.Env),
+			oci.WithMounts([]specs.Mount{
+				{
+					Destination: containerSocketDir,
+					Type:        "bind",
+					Source:      c.hostSocketDir,
+					Options:     []string{"rbind", "rw"},
+				},
+			}),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	c.container = cont
+
+	var stdoutWriter, stderrWriter io.WriteCloser
+	c.stdout, stdoutWriter = io.Pipe()
+	c.stderr, stderrWriter = io.Pipe()
+
+	task, err := cont.NewTask(ctx, cio.NewCreator(cio.WithStreams(nil, stdoutWriter, stderrWriter)))
+	if err != nil {
+		return err
+	}
+	c.task = task
+
+	exitCh, err := task.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	c.exitCh = exitCh
+
+	return task.Start(ctx)
+}
+
+func (c *ContainerdRunner) Wait() error {
+	status := <-c.exitCh
+	return status.Error()
+}
+
+func (c *ContainerdRunner) Kill() error {
+	defer c.client.Close()
+	defer os.RemoveAll(c.hostSocketDir)
+
+	ctx := namespaces.WithNamespace(context.Background(), c.config.Namespace)
+	if c.task != nil {
+		if _, err := c.task.Delete(ctx, containerd.WithProcessKill); err != nil {
+			return err
+		}
+	}
+	if c.container != nil {
+		return c.container.Delete(ctx, containerd.WithSnapshotCleanup)
+	}
+
+	return nil
+}
+
+func (c *ContainerdRunner) Stdout() io.ReadCloser {
+	return c.stdout
+}
+
+func (c *ContainerdRunner) Stderr() io.ReadCloser {
+	return c.stderr
+}
+
+func (c *ContainerdRunner) ResolveAddr(network, address string) (net.Addr, error) {
+	return resolveContainerSocketAddr(c.hostSocketDir, network, address)
+}
+
+func (c *ContainerdRunner) Name() string {
+	return c.image
+}
+
+func (c *ContainerdRunner) ID() string {
+	return c.id
+}
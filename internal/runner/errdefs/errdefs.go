@@ -0,0 +1,118 @@
+// Package errdefs defines the common error interfaces used throughout the
+// runner package, along with helpers to create and check them. This lets
+// callers do errors.As(err, ...) or errdefs.IsNotFound(err) instead of
+// matching on error strings, and unblocks retry/backoff policies and
+// structured logging for embedders.
+package errdefs
+
+import "errors"
+
+// NotFound indicates that the requested object (image, container, exec
+// process, etc.) does not exist.
+type NotFound interface {
+	NotFound()
+}
+
+// Conflict indicates that the request could not be completed due to a
+// conflict with the current state of the target object, for example
+// attempting to exec into a container that has already exited.
+type Conflict interface {
+	Conflict()
+}
+
+// Unauthorized indicates that the caller is not authorized to perform the
+// requested operation, for example pulling an image from a private
+// registry without valid credentials.
+type Unauthorized interface {
+	Unauthorized()
+}
+
+// SystemError indicates a failure in the underlying container runtime
+// itself (the Docker daemon, containerd, or an OCI runtime), as opposed
+// to a problem with the request, for example the container being
+// OOMKilled.
+type SystemError interface {
+	SystemError()
+}
+
+// Causer is implemented by errors that wrap another error, allowing
+// callers to walk the chain to find the underlying cause.
+type Causer interface {
+	Cause() error
+}
+
+type wrapped struct {
+	error
+}
+
+func (w wrapped) Cause() error {
+	return w.error
+}
+
+func (w wrapped) Unwrap() error {
+	return w.error
+}
+
+type notFoundErr struct{ wrapped }
+
+func (notFoundErr) NotFound() {}
+
+// NewNotFound wraps err so that IsNotFound(err) returns true.
+func NewNotFound(err error) error {
+	return notFoundErr{wrapped{err}}
+}
+
+type conflictErr struct{ wrapped }
+
+func (conflictErr) Conflict() {}
+
+// NewConflict wraps err so that IsConflict(err) returns true.
+func NewConflict(err error) error {
+	return conflictErr{wrapped{err}}
+}
+
+type unauthorizedErr struct{ wrapped }
+
+func (unauthorizedErr) Unauthorized() {}
+
+// NewUnauthorized wraps err so that IsUnauthorized(err) returns true.
+func NewUnauthorized(err error) error {
+	return unauthorizedErr{wrapped{err}}
+}
+
+type systemErr struct{ wrapped }
+
+func (systemErr) SystemError() {}
+
+// NewSystemError wraps err so that IsSystemError(err) returns true.
+func NewSystemError(err error) error {
+	return systemErr{wrapped{err}}
+}
+
+// IsNotFound returns true if err, or any error in its chain, implements
+// NotFound.
+func IsNotFound(err error) bool {
+	var e NotFound
+	return errors.As(err, &e)
+}
+
+// IsConflict returns true if err, or any error in its chain, implements
+// Conflict.
+func IsConflict(err error) bool {
+	var e Conflict
+	return errors.As(err, &e)
+}
+
+// IsUnauthorized returns true if err, or any error in its chain,
+// implements Unauthorized.
+func IsUnauthorized(err error) bool {
+	var e Unauthorized
+	return errors.As(err, &e)
+}
+
+// IsSystemError returns true if err, or any error in its chain,
+// implements SystemError.
+func IsSystemError(err error) bool {
+	var e SystemError
+	return errors.As(err, &e)
+}
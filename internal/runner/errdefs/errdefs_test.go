@@ -0,0 +1,43 @@
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsHelpersMatchWrappedErrors(t *testing.T) {
+	cause := errors.New("boom")
+
+	cases := []struct {
+		name string
+		err  error
+		is   func(error) bool
+	}{
+		{"not found", NewNotFound(cause), IsNotFound},
+		{"conflict", NewConflict(cause), IsConflict},
+		{"unauthorized", NewUnauthorized(cause), IsUnauthorized},
+		{"system error", NewSystemError(cause), IsSystemError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.is(tc.err) {
+				t.Fatalf("expected %v to match its own Is helper", tc.err)
+			}
+
+			wrapped := fmt.Errorf("context: %w", tc.err)
+			if !tc.is(wrapped) {
+				t.Fatalf("expected wrapped error %v to still match its Is helper", wrapped)
+			}
+
+			if !errors.Is(tc.err, cause) {
+				t.Fatalf("expected %v to unwrap to the original cause", tc.err)
+			}
+		})
+	}
+
+	if IsNotFound(cause) {
+		t.Fatal("expected a plain error to not match IsNotFound")
+	}
+}
@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin/config"
+	"github.com/hashicorp/go-plugin/internal/runner/errdefs"
+)
+
+var _ Runner = (*OCIRunner)(nil)
+
+// ociPollInterval is how often Wait polls the runtime's state command for
+// the container's status, since OCI runtimes have no blocking wait
+// equivalent to a Docker container wait.
+const ociPollInterval = 500 * time.Millisecond
+
+// OCIRunner implements the Runner interface by driving an OCI runtime
+// binary (runc, crun, youki, ...) directly against a prepared bundle
+// directory, skipping Docker and containerd entirely. This gives a much
+// smaller dependency footprint, suiting embedded and rootless scenarios
+// where a full container daemon isn't acceptable.
+type OCIRunner struct {
+	logger hclog.Logger
+
+	cmd    *exec.Cmd
+	config *config.OCIConfig
+
+	hostSocketDir string
+	bundleDir     string
+
+	stdout io.ReadCloser
+	stderr io.ReadCloser
+
+	id string
+}
+
+// NewOCIRunner must be passed a cmd that hasn't yet been started. It
+// prepares an OCI bundle directory containing a minimal config.json spec
+// derived from cfg, bind-mounting hostSocketDir into the container's
+// rootfs.
+func NewOCIRunner(logger hclog.Logger, cmd *exec.Cmd, cfg *config.OCIConfig, hostSocketDir string) (*OCIRunner, error) {
+	id := newRunnerID()
+
+	bundleDir, err := os.MkdirTemp("", "go-plugin-oci-")
+	if err != nil {
+		return nil, err
+	}
+
+	injectUnixSocketEnv(cmd, cfg.UnixSocketGroup)
+
+	spec := newOCISpec(cfg, cmd, hostSocketDir)
+	specBytes, err := json.MarshalIndent(spec, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(bundleDir, "config.json"), specBytes, 0o644); err != nil {
+		return nil, err
+	}
+
+	return &OCIRunner{
+		logger:        logger,
+		cmd:           cmd,
+		config:        cfg,
+		hostSocketDir: hostSocketDir,
+		bundleDir:     bundleDir,
+		id:            id,
+	}, nil
+}
+
+// newOCISpec builds a minimal OCI runtime spec that runs cmd inside
+// cfg.RootfsPath, bind-mounting hostSocketDir onto containerSocketDir so
+// the plugin can expose its Unix socket to the host.
+func newOCISpec(cfg *config.OCIConfig, cmd *exec.Cmd, hostSocketDir string) *specs.Spec {
+	return &specs.Spec{
+		Version: specs.Version,
+		Root: &specs.Root{
+			Path: cfg.RootfsPath,
+		},
+		Process: &specs.Process{
+			Args: append([]string{cmd.Path}, cmd.Args[1:]...),
+			Env:  cmd.Env,
+			Cwd:  "/",
+		},
+		Mounts: append(cfg.Mounts, specs.Mount{
+			Destination: containerSocketDir,
+			Type:        "bind",
+			Source:      hostSocketDir,
+			Options:     []string{"rbind", "rw"},
+		}),
+	}
+}
+
+func (c *OCIRunner) Start() error {
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	c.stdout = stdoutR
+	c.stderr = stderrR
+
+	createCmd := exec.Command(c.config.Runtime, "create", "--bundle", c.bundleDir, c.id)
+	createCmd.Stdout = stdoutW
+	createCmd.Stderr = stderrW
+	createErr := createCmd.Run()
+	// Close our copies of the write ends now that create has exited, so
+	// that Stdout()/Stderr() readers see EOF instead of blocking forever
+	// on a descriptor only this process still holds open.
+	stdoutW.Close()
+	stderrW.Close()
+	if createErr != nil {
+		return errdefs.NewSystemError(createErr)
+	}
+
+	startCmd := exec.Command(c.config.Runtime, "start", c.id)
+	if err := startCmd.Run(); err != nil {
+		return errdefs.NewSystemError(err)
+	}
+
+	return nil
+}
+
+func (c *OCIRunner) Wait() error {
+	for {
+		out, err := exec.Command(c.config.Runtime, "state", c.id).Output()
+		if err != nil {
+			return errdefs.NewNotFound(err)
+		}
+
+		var state specs.State
+		if err := json.Unmarshal(out, &state); err != nil {
+			return err
+		}
+
+		if state.Status == specs.StateStopped {
+			return nil
+		}
+
+		time.Sleep(ociPollInterval)
+	}
+}
+
+func (c *OCIRunner) Kill() error {
+	defer os.RemoveAll(c.hostSocketDir)
+	defer os.RemoveAll(c.bundleDir)
+
+	// kill commonly fails because the container has already stopped, since
+	// the ordinary flow is Wait() returning after the runtime itself
+	// reports the container as stopped, followed by the host calling
+	// Kill() to clean up. Attempt delete regardless of that outcome, or
+	// the runtime's own container/state registration is leaked on that
+	// path.
+	_ = exec.Command(c.config.Runtime, "kill", c.id, "KILL").Run()
+
+	return exec.Command(c.config.Runtime, "delete", c.id).Run()
+}
+
+func (c *OCIRunner) Stdout() io.ReadCloser {
+	return c.stdout
+}
+
+func (c *OCIRunner) Stderr() io.ReadCloser {
+	return c.stderr
+}
+
+func (c *OCIRunner) ResolveAddr(network, address string) (net.Addr, error) {
+	return resolveContainerSocketAddr(c.hostSocketDir, network, address)
+}
+
+func (c *OCIRunner) Name() string {
+	return c.config.Runtime
+}
+
+func (c *OCIRunner) ID() string {
+	return c.id
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-plugin/internal/constants"
+	"github.com/hashicorp/go-plugin/internal/runner/errdefs"
+)
+
+// containerSocketDir is the path inside the container, regardless of
+// which container runtime is driving it, where the host directory
+// containing the plugin's Unix socket is mounted.
+const containerSocketDir = "/tmp"
+
+// idCounter is incremented for every newRunnerID call, so that runners
+// started concurrently from the same host process don't collide.
+var idCounter uint64
+
+// newRunnerID returns an identifier unique to this runner instance, for
+// runner backends (ContainerdRunner, OCIRunner) that must name their
+// container/task themselves rather than letting a daemon assign a name.
+// pid alone isn't enough, since a single host process commonly launches
+// more than one plugin.
+func newRunnerID() string {
+	n := atomic.AddUint64(&idCounter, 1)
+	return fmt.Sprintf("go-plugin-%d-%d", os.Getpid(), n)
+}
+
+// injectUnixSocketEnv appends the environment variables that tell the
+// plugin binary where to create its Unix socket once it's running inside
+// a container, so the host process can reach it via the corresponding
+// bind mount set up by the caller.
+func injectUnixSocketEnv(cmd *exec.Cmd, unixSocketGroup int) {
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", constants.EnvUnixSocketDir, containerSocketDir))
+	if unixSocketGroup != 0 {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", constants.EnvUnixSocketGroup, unixSocketGroup))
+	}
+}
+
+// resolveContainerSocketAddr implements the ResolveAddr contract shared by
+// every container/OCI-backed Runner: the plugin reports back a
+// PLUGIN_UNIX_SOCKET_DIR-relative path over the wire, which must be
+// rejoined onto hostSocketDir, since the container and host don't share a
+// filesystem view of where the bind mount set up by injectUnixSocketEnv's
+// caller actually lives on the host side.
+func resolveContainerSocketAddr(hostSocketDir, network, address string) (net.Addr, error) {
+	switch network {
+	case "unix":
+		if !strings.HasPrefix(address, "PLUGIN_UNIX_SOCKET_DIR:") {
+			return nil, errdefs.NewNotFound(errors.New("plugin is running inside container but needs an update to be compatible"))
+		}
+
+		address = path.Join(hostSocketDir, strings.TrimPrefix(address, "PLUGIN_UNIX_SOCKET_DIR:"))
+		return net.ResolveUnixAddr("unix", address)
+	default:
+		return nil, fmt.Errorf("unsupported address: %s, %s", network, address)
+	}
+}
@@ -0,0 +1,258 @@
+package runner
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+var _ Runner = (*Supervisor)(nil)
+
+// RunnerFactory creates a fresh Runner instance ready to be Start()ed.
+// Supervisor calls it once up front and again every time the previous
+// Runner's process needs to be replaced after an unexpected exit.
+type RunnerFactory func() (Runner, error)
+
+// HealthCheckFunc probes a freshly (re)started plugin for liveness. It's
+// given the address negotiated by HandshakeFunc, and should return a
+// non-nil error if the plugin isn't healthy yet.
+type HealthCheckFunc func(addr net.Addr) error
+
+// HandshakeFunc performs the go-plugin handshake against a freshly
+// (re)started Runner and returns the address it negotiated. Supervisor
+// has no access to the handshake logic that lives in client.Client, so a
+// HandshakeFunc must be supplied via WithHandshake whenever a
+// HealthCheckFunc is configured, or there's no real address to probe.
+type HandshakeFunc func(Runner) (net.Addr, error)
+
+// BackoffPolicy computes the delay to wait before the attempt'th restart
+// attempt (attempt starts at 1).
+type BackoffPolicy func(attempt int) time.Duration
+
+// OnRestartFunc is called before every restart attempt, successful or
+// not, so hosts can log or gate on repeated failures.
+type OnRestartFunc func(attempt int, lastErr error)
+
+// DefaultBackoffPolicy doubles the delay each attempt, starting at 500ms
+// and capping at 1 minute.
+func DefaultBackoffPolicy(attempt int) time.Duration {
+	d := 500 * time.Millisecond
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d > time.Minute {
+			return time.Minute
+		}
+	}
+	return d
+}
+
+// Supervisor wraps a Runner and restarts it, using factory, if its
+// process exits unexpectedly. It implements the Runner interface itself,
+// transparently delegating to whichever underlying Runner instance is
+// currently active. This is especially valuable for the ContainerRunner
+// family, where transient daemon errors or OOMKills are common.
+type Supervisor struct {
+	logger  hclog.Logger
+	factory RunnerFactory
+
+	healthCheck HealthCheckFunc
+	handshake   HandshakeFunc
+	backoff     BackoffPolicy
+	onRestart   OnRestartFunc
+
+	mu      sync.Mutex
+	current Runner
+	killed  bool
+}
+
+// SupervisorOption configures optional Supervisor behaviour.
+type SupervisorOption func(*Supervisor)
+
+// WithHealthCheck installs a health probe that a restarted Runner must
+// pass before Supervisor will consider it ready. Requires WithHandshake
+// to also be supplied, since the probe needs a real negotiated address.
+func WithHealthCheck(hc HealthCheckFunc) SupervisorOption {
+	return func(s *Supervisor) { s.healthCheck = hc }
+}
+
+// WithHandshake installs the callback Supervisor uses to negotiate an
+// address with a restarted Runner before handing it to HealthCheckFunc.
+func WithHandshake(hs HandshakeFunc) SupervisorOption {
+	return func(s *Supervisor) { s.handshake = hs }
+}
+
+// WithBackoffPolicy overrides DefaultBackoffPolicy.
+func WithBackoffPolicy(b BackoffPolicy) SupervisorOption {
+	return func(s *Supervisor) { s.backoff = b }
+}
+
+// WithOnRestart installs a callback invoked before each restart attempt.
+func WithOnRestart(f OnRestartFunc) SupervisorOption {
+	return func(s *Supervisor) { s.onRestart = f }
+}
+
+// NewSupervisor wraps the Runner produced by factory, which is called
+// once immediately to obtain the initial Runner.
+func NewSupervisor(logger hclog.Logger, factory RunnerFactory, opts ...SupervisorOption) (*Supervisor, error) {
+	s := &Supervisor{
+		logger:  logger,
+		factory: factory,
+		backoff: DefaultBackoffPolicy,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.healthCheck != nil && s.handshake == nil {
+		return nil, errors.New("runner: WithHealthCheck requires WithHandshake")
+	}
+
+	r, err := factory()
+	if err != nil {
+		return nil, err
+	}
+	s.current = r
+
+	return s, nil
+}
+
+func (s *Supervisor) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.Start()
+}
+
+// Wait blocks until the wrapped Runner's process exits for good: either
+// it exited cleanly (its Wait returned nil), or Kill was called.
+// Exits with a non-nil error are treated as unexpected and retried
+// internally according to the configured BackoffPolicy and
+// HealthCheckFunc, and are not returned to the caller; restart keeps
+// retrying until it succeeds or Kill is called.
+func (s *Supervisor) Wait() error {
+	for {
+		err := s.current.Wait()
+
+		s.mu.Lock()
+		killed := s.killed
+		s.mu.Unlock()
+		if killed {
+			return err
+		}
+		if err == nil {
+			return nil
+		}
+
+		if restartErr := s.restart(err); restartErr != nil {
+			return restartErr
+		}
+	}
+}
+
+// restart re-invokes factory and Start, retrying with s.backoff until a
+// restarted Runner comes up and passes the health check, if any. It
+// aborts as soon as Kill is called, rather than retrying forever and
+// potentially starting a new plugin process after the host already asked
+// to shut down.
+func (s *Supervisor) restart(lastErr error) error {
+	for attempt := 1; ; attempt++ {
+		if s.isKilled() {
+			return lastErr
+		}
+
+		if s.onRestart != nil {
+			s.onRestart(attempt, lastErr)
+		}
+		time.Sleep(s.backoff(attempt))
+
+		if s.isKilled() {
+			return lastErr
+		}
+
+		r, err := s.factory()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err := r.Start(); err != nil {
+			lastErr = err
+			continue
+		}
+
+		if s.healthCheck != nil {
+			addr, err := s.handshake(r)
+			if err == nil {
+				err = s.healthCheck(addr)
+			}
+			if err != nil {
+				r.Kill()
+				lastErr = err
+				continue
+			}
+		}
+
+		s.mu.Lock()
+		if s.killed {
+			s.mu.Unlock()
+			r.Kill()
+			return lastErr
+		}
+		old := s.current
+		s.current = r
+		s.mu.Unlock()
+
+		// The old Runner is dead (that's why we're here), but its
+		// resources -- e.g. ContainerRunner's dockerClient and
+		// hostSocketDir -- are only released by Kill, so it must still be
+		// called before we drop the only reference to it.
+		old.Kill()
+		return nil
+	}
+}
+
+func (s *Supervisor) isKilled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.killed
+}
+
+func (s *Supervisor) Kill() error {
+	s.mu.Lock()
+	s.killed = true
+	current := s.current
+	s.mu.Unlock()
+	return current.Kill()
+}
+
+func (s *Supervisor) Stdout() io.ReadCloser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.Stdout()
+}
+
+func (s *Supervisor) Stderr() io.ReadCloser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.Stderr()
+}
+
+func (s *Supervisor) ResolveAddr(network, address string) (net.Addr, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.ResolveAddr(network, address)
+}
+
+func (s *Supervisor) Name() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.Name()
+}
+
+func (s *Supervisor) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current.ID()
+}
@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// fakeRunner is a minimal Runner double for exercising Supervisor without
+// a real container/process backing it.
+type fakeRunner struct {
+	name   string
+	killed bool
+}
+
+func (f *fakeRunner) Start() error { return nil }
+func (f *fakeRunner) Wait() error  { return nil }
+func (f *fakeRunner) Kill() error  { f.killed = true; return nil }
+
+func (f *fakeRunner) Stdout() io.ReadCloser { return nil }
+func (f *fakeRunner) Stderr() io.ReadCloser { return nil }
+
+func (f *fakeRunner) ResolveAddr(network, address string) (net.Addr, error) {
+	return &net.UnixAddr{Name: address, Net: network}, nil
+}
+
+func (f *fakeRunner) Name() string { return f.name }
+func (f *fakeRunner) ID() string   { return f.name }
+
+func noBackoff(int) time.Duration { return 0 }
+
+// TestSupervisorRestartUsesHandshakeForHealthCheck guards against
+// restart() health-checking against an address obtained by calling
+// ResolveAddr directly, which has no real handshake-negotiated address to
+// translate and therefore always fails.
+func TestSupervisorRestartUsesHandshakeForHealthCheck(t *testing.T) {
+	factory := func() (Runner, error) { return &fakeRunner{}, nil }
+
+	var handshakeCalls, healthCheckCalls int
+	s, err := NewSupervisor(hclog.NewNullLogger(), factory,
+		WithBackoffPolicy(noBackoff),
+		WithHandshake(func(r Runner) (net.Addr, error) {
+			handshakeCalls++
+			return r.ResolveAddr("unix", "negotiated")
+		}),
+		WithHealthCheck(func(addr net.Addr) error {
+			healthCheckCalls++
+			if addr == nil || addr.String() == "" {
+				t.Fatalf("health check got no negotiated address: %v", addr)
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewSupervisor: %v", err)
+	}
+
+	if err := s.restart(errors.New("unexpected exit")); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if handshakeCalls == 0 {
+		t.Fatal("expected HandshakeFunc to be called during restart")
+	}
+	if healthCheckCalls == 0 {
+		t.Fatal("expected HealthCheckFunc to be called during restart")
+	}
+}
+
+func TestNewSupervisorRequiresHandshakeForHealthCheck(t *testing.T) {
+	factory := func() (Runner, error) { return &fakeRunner{}, nil }
+
+	_, err := NewSupervisor(hclog.NewNullLogger(), factory, WithHealthCheck(func(net.Addr) error { return nil }))
+	if err == nil {
+		t.Fatal("expected error when WithHealthCheck is used without WithHandshake")
+	}
+}
+
+// TestSupervisorRestartKillsReplacedRunner guards against a restart
+// silently dropping the reference to the dead Runner it's replacing
+// without calling Kill on it, which would leak that Runner's resources
+// (e.g. a ContainerRunner's dockerClient and hostSocketDir).
+func TestSupervisorRestartKillsReplacedRunner(t *testing.T) {
+	old := &fakeRunner{name: "old"}
+	factory := func() (Runner, error) { return &fakeRunner{name: "new"}, nil }
+
+	s, err := NewSupervisor(hclog.NewNullLogger(), factory, WithBackoffPolicy(noBackoff))
+	if err != nil {
+		t.Fatalf("NewSupervisor: %v", err)
+	}
+	s.current = old
+
+	if err := s.restart(errors.New("unexpected exit")); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+
+	if !old.killed {
+		t.Fatal("expected restart to Kill the Runner it replaced")
+	}
+	if s.current.ID() != "new" {
+		t.Fatalf("expected current Runner to be the freshly restarted one, got %q", s.current.ID())
+	}
+}
+
+// TestSupervisorRestartAbortsOnceKilled guards against restart retrying
+// forever (and potentially starting a brand-new plugin process) after
+// Kill has already been called.
+func TestSupervisorRestartAbortsOnceKilled(t *testing.T) {
+	factoryCalls := 0
+	factory := func() (Runner, error) {
+		factoryCalls++
+		return &fakeRunner{name: "new"}, nil
+	}
+
+	s, err := NewSupervisor(hclog.NewNullLogger(), factory, WithBackoffPolicy(noBackoff))
+	if err != nil {
+		t.Fatalf("NewSupervisor: %v", err)
+	}
+	factoryCalls = 0 // Reset; NewSupervisor's own factory call doesn't count.
+
+	s.killed = true
+	lastErr := errors.New("unexpected exit")
+	if err := s.restart(lastErr); err != lastErr {
+		t.Fatalf("restart() = %v, want %v", err, lastErr)
+	}
+	if factoryCalls != 0 {
+		t.Fatalf("expected restart to abort before calling factory again, called %d times", factoryCalls)
+	}
+}
+
+func TestDefaultBackoffPolicyDoublesAndCaps(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{20, time.Minute},
+	}
+
+	for _, tc := range cases {
+		if got := DefaultBackoffPolicy(tc.attempt); got != tc.want {
+			t.Errorf("DefaultBackoffPolicy(%d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+}